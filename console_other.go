@@ -0,0 +1,7 @@
+//go:build !windows
+
+package termlog
+
+// enableConsole is a no-op outside Windows - every other supported platform
+// already honours ANSI escape sequences natively on a terminal.
+func enableConsole() {}
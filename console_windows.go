@@ -0,0 +1,30 @@
+//go:build windows
+
+package termlog
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
+	"golang.org/x/sys/windows"
+)
+
+// enableConsole tries to turn on VT100/ANSI processing for the Windows
+// console, so escape sequences - coloured output, and the cursor control
+// Progress and Spinner rely on - work directly against os.Stdout, the way
+// they already do on every other platform. Consoles too old to support
+// ENABLE_VIRTUAL_TERMINAL_PROCESSING (pre-Windows 10) fall back to
+// go-colorable, which translates the same escape sequences into Win32
+// console API calls instead.
+func enableConsole() {
+	stdout := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(stdout, &mode); err != nil {
+		color.Output = colorable.NewColorable(os.Stdout)
+		return
+	}
+	if err := windows.SetConsoleMode(stdout, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		color.Output = colorable.NewColorable(os.Stdout)
+	}
+}
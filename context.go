@@ -5,19 +5,43 @@ import "context"
 // contextKey is the type used for storing termlog in context
 type contextKey struct{}
 
+// fieldsKey is the type used for storing fields attached with WithFields in
+// context.
+type fieldsKey struct{}
+
 // NewContext creates a new context with an included Logger
 func NewContext(ctx context.Context, logger Logger) context.Context {
 	return context.WithValue(ctx, contextKey{}, logger)
 }
 
+// WithFields returns a copy of ctx carrying kv merged with any fields
+// already attached by a previous WithFields call. It does not set or
+// require a Logger - FromContext applies the accumulated fields to whatever
+// Logger it finds, via Logger.With. This lets middleware thread
+// request-scoped fields (request id, user, trace id) through a context
+// without having to fetch and rewrap the logger itself.
+func WithFields(ctx context.Context, kv ...interface{}) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]KV)
+	merged := append(append([]KV{}, existing...), kvFields(kv)...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
 // FromContext retrieves a Logger from a context. If no logger is present, we
-// return a new silenced logger that will produce no output.
+// return a new silenced logger that will produce no output. Any fields
+// attached with WithFields are applied to the returned Logger via With.
 func FromContext(ctx context.Context) Logger {
 	logger, ok := ctx.Value(contextKey{}).(Logger)
 	if !ok {
 		l := NewLog()
 		l.Quiet()
-		return l
+		logger = l
+	}
+	if fields, ok := ctx.Value(fieldsKey{}).([]KV); ok && len(fields) > 0 {
+		kv := make([]interface{}, 0, len(fields)*2)
+		for _, f := range fields {
+			kv = append(kv, f.Key, f.Value)
+		}
+		logger = logger.With(kv...)
 	}
 	return logger
 }
@@ -0,0 +1,151 @@
+package termlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KV is a single structured key/value pair attached to a log Entry.
+type KV struct {
+	Key   string
+	Value interface{}
+}
+
+// kvFields turns a flat key/value variadic list (as passed to SayKV and
+// friends) into a slice of KV pairs. A trailing key with no matching value
+// is recorded with a nil Value.
+func kvFields(kv []interface{}) []KV {
+	fields := make([]KV, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, _ := kv[i].(string)
+		var val interface{}
+		if i+1 < len(kv) {
+			val = kv[i+1]
+		}
+		fields = append(fields, KV{Key: key, Value: val})
+	}
+	return fields
+}
+
+// Entry is a single log entry, passed to a Handler for rendering.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Stream  string
+	Message string
+	Fields  []KV
+	// First is true for the first line of a Say/Notice/Warn/Shout call or a
+	// Group's Done(); later lines in the same Group are continuations.
+	First bool
+}
+
+// Handler formats and writes log entries. termlog ships three built-ins: the
+// default coloured terminal handler, NewJSONHandler and NewLogfmtHandler.
+// Custom handlers can be installed with NewLogWithHandler or Log.SetHandler.
+type Handler interface {
+	// Handle renders a single Entry. It is only called for entries that
+	// pass Enabled.
+	Handle(Entry)
+	// Enabled reports whether entries at the given level should be passed
+	// to Handle.
+	Enabled(Level) bool
+}
+
+// terminalHandler is the default Handler, rendering coloured, human-readable
+// output the way termlog always has.
+type terminalHandler struct {
+	log *Log
+}
+
+func (t *terminalHandler) Enabled(Level) bool {
+	return true
+}
+
+func (t *terminalHandler) Handle(e Entry) {
+	col := t.log.color(e.Level)
+	if e.First {
+		t.log.Palette.Timestamp.Printf("%s", e.Time.Format(t.log.TimeFmt))
+	} else {
+		col.Printf(indent)
+	}
+	col.Printf("%s", e.Message)
+	for _, f := range e.Fields {
+		col.Printf(" %s=%v", f.Key, f.Value)
+	}
+	col.Printf("\n")
+}
+
+// jsonHandler renders entries as one JSON object per line.
+type jsonHandler struct {
+	w io.Writer
+}
+
+// NewJSONHandler returns a Handler that writes each Entry as a single JSON
+// object per line, suitable for log aggregators that scrape stdout.
+func NewJSONHandler(w io.Writer) Handler {
+	return &jsonHandler{w: w}
+}
+
+func (j *jsonHandler) Enabled(Level) bool {
+	return true
+}
+
+func (j *jsonHandler) Handle(e Entry) {
+	m := make(map[string]interface{}, len(e.Fields)+4)
+	m["time"] = e.Time.Format(time.RFC3339)
+	m["level"] = e.Level.String()
+	if e.Stream != "" {
+		m["stream"] = e.Stream
+	}
+	m["msg"] = e.Message
+	for _, f := range e.Fields {
+		m[f.Key] = f.Value
+	}
+	buf, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	j.w.Write(append(buf, '\n'))
+}
+
+// logfmtHandler renders entries as logfmt (key=value) lines.
+type logfmtHandler struct {
+	w io.Writer
+}
+
+// NewLogfmtHandler returns a Handler that writes each Entry as a single
+// logfmt-encoded line.
+func NewLogfmtHandler(w io.Writer) Handler {
+	return &logfmtHandler{w: w}
+}
+
+func (l *logfmtHandler) Enabled(Level) bool {
+	return true
+}
+
+func (l *logfmtHandler) Handle(e Entry) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s", e.Time.Format(time.RFC3339), e.Level.String())
+	if e.Stream != "" {
+		fmt.Fprintf(&b, " stream=%s", logfmtQuote(e.Stream))
+	}
+	fmt.Fprintf(&b, " msg=%s", logfmtQuote(e.Message))
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%s", f.Key, logfmtQuote(fmt.Sprint(f.Value)))
+	}
+	b.WriteByte('\n')
+	l.w.Write([]byte(b.String()))
+}
+
+// logfmtQuote quotes a value if it contains characters that would make the
+// logfmt line ambiguous to parse.
+func logfmtQuote(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
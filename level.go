@@ -0,0 +1,46 @@
+package termlog
+
+import "strings"
+
+// Level indicates the severity of a log entry.
+type Level int
+
+// Severity levels, in increasing order of severity.
+const (
+	LevelSay Level = iota
+	LevelNotice
+	LevelWarn
+	LevelShout
+)
+
+// String returns the lower-case name of the level, as used by the logfmt
+// and JSON handlers.
+func (lv Level) String() string {
+	switch lv {
+	case LevelSay:
+		return "say"
+	case LevelNotice:
+		return "notice"
+	case LevelWarn:
+		return "warn"
+	case LevelShout:
+		return "shout"
+	}
+	return "unknown"
+}
+
+// parseLevel parses a level name (case-insensitive) as used in the TERMLOG
+// env var, e.g. "warn".
+func parseLevel(s string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "say":
+		return LevelSay, true
+	case "notice":
+		return LevelNotice, true
+	case "warn":
+		return LevelWarn, true
+	case "shout":
+		return LevelShout, true
+	}
+	return LevelSay, false
+}
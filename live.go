@@ -0,0 +1,57 @@
+package termlog
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+// liveRegion is a pinned, repeatedly-repainted line anchored at the bottom
+// of the terminal - the shared machinery behind Progress and Spinner.
+// Log.output coordinates with the active liveRegion under l.mu, so ordinary
+// Say/Notice/Warn/Shout lines scroll above the pinned line instead of
+// corrupting it.
+type liveRegion interface {
+	// renderLocked returns the current contents of the region, including a
+	// leading "\r" and no trailing newline. l.mu is held by the caller.
+	renderLocked() string
+}
+
+// startLive installs r as l's active live region and paints its first
+// frame, if l is capable of live repainting. Non-interactive logs (not a
+// terminal, colour disabled, or a non-default handler) skip this entirely;
+// callers are expected to print a single summary line from Done() instead.
+func (l *Log) startLive(r liveRegion) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.liveCapable() {
+		return false
+	}
+	l.live = r
+	fmt.Fprint(color.Output, r.renderLocked())
+	return true
+}
+
+// repaintLive erases and redraws l's active live region, if r is still the
+// active one. Called by Progress/Spinner whenever their own state changes.
+func (l *Log) repaintLive(r liveRegion) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.live != r {
+		return
+	}
+	fmt.Fprint(color.Output, "\r\033[2K")
+	fmt.Fprint(color.Output, r.renderLocked())
+}
+
+// endLive erases the live region (if r is still active) and clears it, so
+// that subsequent output resumes scrolling normally.
+func (l *Log) endLive(r liveRegion) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.live != r {
+		return
+	}
+	fmt.Fprint(color.Output, "\r\033[2K")
+	l.live = nil
+}
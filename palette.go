@@ -0,0 +1,105 @@
+package termlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ColorSpec names a single foreground or background colour for a
+// PaletteEntry: one of fatih/color's 16 standard Attribute colours, an
+// xterm 256-colour palette index, or a 24-bit truecolor RGB value.
+type ColorSpec struct {
+	attr    color.Attribute
+	idx     *uint8
+	r, g, b uint8
+	isRGB   bool
+}
+
+// Color256 builds a ColorSpec from an xterm 256-colour palette index
+// (0-255).
+func Color256(n uint8) *ColorSpec {
+	return &ColorSpec{idx: &n}
+}
+
+// sgr returns the SGR parameter for this ColorSpec as a foreground colour
+// if fg is true, or a background colour otherwise.
+func (c *ColorSpec) sgr(fg bool) string {
+	switch {
+	case c.isRGB:
+		if fg {
+			return fmt.Sprintf("38;2;%d;%d;%d", c.r, c.g, c.b)
+		}
+		return fmt.Sprintf("48;2;%d;%d;%d", c.r, c.g, c.b)
+	case c.idx != nil:
+		if fg {
+			return fmt.Sprintf("38;5;%d", *c.idx)
+		}
+		return fmt.Sprintf("48;5;%d", *c.idx)
+	default:
+		return strconv.Itoa(int(c.attr))
+	}
+}
+
+// PaletteEntry is a single colour used in a Palette. Where a bare
+// fatih/color Attribute is limited to the 16 standard ANSI colours, a
+// PaletteEntry's FG and BG can also carry a 256-colour index or a 24-bit
+// truecolor RGB value, for the terminals that support it - which these days
+// is most of them, Windows Terminal and VS Code's integrated terminal
+// included.
+type PaletteEntry struct {
+	FG     *ColorSpec
+	BG     *ColorSpec
+	Bold   bool
+	Italic bool
+}
+
+// NewPaletteEntry wraps one of fatih/color's 16 standard Attribute colours
+// (e.g. color.FgBlue) in a PaletteEntry.
+func NewPaletteEntry(attr color.Attribute) *PaletteEntry {
+	return &PaletteEntry{FG: &ColorSpec{attr: attr}}
+}
+
+// RGB builds a PaletteEntry with a 24-bit truecolor foreground.
+func RGB(r, g, b uint8) *PaletteEntry {
+	return &PaletteEntry{FG: &ColorSpec{r: r, g: g, b: b, isRGB: true}}
+}
+
+// sequence renders p's SGR parameters, without the leading "\x1b[" or
+// trailing "m". An entry with nothing set (no FG, BG, Bold or Italic)
+// renders as "0" (reset), matching fatih/color's treatment of a bare
+// color.New().
+func (p *PaletteEntry) sequence() string {
+	var codes []string
+	if p.Bold {
+		codes = append(codes, "1")
+	}
+	if p.Italic {
+		codes = append(codes, "3")
+	}
+	if p.FG != nil {
+		codes = append(codes, p.FG.sgr(true))
+	}
+	if p.BG != nil {
+		codes = append(codes, p.BG.sgr(false))
+	}
+	if len(codes) == 0 {
+		codes = append(codes, "0")
+	}
+	return strings.Join(codes, ";")
+}
+
+func (p *PaletteEntry) wrap(s string) string {
+	if color.NoColor || s == "" {
+		return s
+	}
+	return "\x1b[" + p.sequence() + "m" + s + "\x1b[0m"
+}
+
+// Printf formats according to format and writes the result to color.Output,
+// wrapped in p's SGR sequence unless color.NoColor is set.
+func (p *PaletteEntry) Printf(format string, a ...interface{}) {
+	fmt.Fprint(color.Output, p.wrap(fmt.Sprintf(format, a...)))
+}
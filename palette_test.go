@@ -0,0 +1,52 @@
+package termlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestPaletteRGB(t *testing.T) {
+	prev := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = prev }()
+
+	e := RGB(255, 128, 0)
+	got := e.wrap("hi")
+	if !strings.Contains(got, "38;2;255;128;0") {
+		t.Errorf("expected truecolor SGR sequence, got %q", got)
+	}
+}
+
+func TestPaletteColor256(t *testing.T) {
+	prev := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = prev }()
+
+	e := &PaletteEntry{FG: Color256(202)}
+	got := e.wrap("hi")
+	if !strings.Contains(got, "38;5;202") {
+		t.Errorf("expected 256-colour SGR sequence, got %q", got)
+	}
+}
+
+func TestPaletteNoColor(t *testing.T) {
+	prev := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = prev }()
+
+	e := RGB(255, 128, 0)
+	if got := e.wrap("hi"); got != "hi" {
+		t.Errorf("expected no escape codes with NoColor set, got %q", got)
+	}
+}
+
+func TestNewLogHonorsNoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	l := NewLog()
+	if !color.NoColor {
+		t.Error("expected NO_COLOR to disable colour")
+	}
+	_ = l
+}
@@ -0,0 +1,131 @@
+package termlog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// Progress is a pinned, repainted progress bar for a long-running task with
+// a known size - a file transfer, a build step counting files, and so on.
+// On a real terminal it repaints in place, showing a bar, a rate and an
+// ETA; everywhere else it prints a single summary line when Done is called.
+type Progress interface {
+	// Set sets the current progress to n, out of the total given to
+	// NewProgress, and repaints the bar.
+	Set(n int64)
+	// Add adds n to the current progress and repaints the bar.
+	Add(n int64)
+	// Done finalises the bar, leaving a single summary line in the scrollback.
+	Done()
+}
+
+type progress struct {
+	mu      sync.Mutex
+	log     *Log
+	header  string
+	total   int64
+	current int64
+	start   time.Time
+	live    bool
+}
+
+// NewProgress creates a Progress bar with the given header and total, and
+// starts repainting it immediately.
+func (l *Log) NewProgress(header string, total int64) Progress {
+	p := &progress{log: l, header: header, total: total, start: time.Now()}
+	p.live = l.startLive(p)
+	return p
+}
+
+func (p *progress) Set(n int64) {
+	p.mu.Lock()
+	p.current = n
+	p.mu.Unlock()
+	if p.live {
+		p.log.repaintLive(p)
+	}
+}
+
+func (p *progress) Add(n int64) {
+	p.mu.Lock()
+	p.current += n
+	p.mu.Unlock()
+	if p.live {
+		p.log.repaintLive(p)
+	}
+}
+
+func (p *progress) Done() {
+	if p.live {
+		p.log.endLive(p)
+	}
+	p.mu.Lock()
+	summary := p.summary()
+	p.mu.Unlock()
+	p.log.emit("", LevelSay, nil, "%s", []interface{}{summary})
+}
+
+// renderLocked implements liveRegion. p.mu is not held by the caller, so it
+// takes its own lock.
+func (p *progress) renderLocked() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return "\r" + p.bar()
+}
+
+// bar renders the bar, rate and ETA. p.mu must be held by the caller.
+func (p *progress) bar() string {
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %d (%s/s)", p.header, p.current, formatRate(p.rate()))
+	}
+	frac := float64(p.current) / float64(p.total)
+	if frac > 1 {
+		frac = 1
+	}
+	if frac < 0 {
+		frac = 0
+	}
+	filled := int(frac * progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	return fmt.Sprintf(
+		"%s [%s] %d/%d (%s/s, eta %s)",
+		p.header, bar, p.current, p.total, formatRate(p.rate()), formatETA(p.current, p.total, p.rate()),
+	)
+}
+
+// summary is the single line left behind once the bar is Done. p.mu must be
+// held by the caller.
+func (p *progress) summary() string {
+	elapsed := time.Since(p.start).Round(time.Second)
+	if p.total <= 0 {
+		return fmt.Sprintf("%s: %d in %s", p.header, p.current, elapsed)
+	}
+	return fmt.Sprintf("%s: %d/%d in %s", p.header, p.current, p.total, elapsed)
+}
+
+// rate is the current items/sec, computed from p.current and p.start. p.mu
+// must be held by the caller.
+func (p *progress) rate() float64 {
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(p.current) / elapsed
+}
+
+func formatRate(r float64) string {
+	return fmt.Sprintf("%.1f", r)
+}
+
+// formatETA estimates the time remaining to reach total at the given rate.
+func formatETA(current, total int64, rate float64) string {
+	if rate <= 0 || total <= current {
+		return "-"
+	}
+	remaining := float64(total-current) / rate
+	return time.Duration(remaining * float64(time.Second)).Round(time.Second).String()
+}
@@ -0,0 +1,64 @@
+package termlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressNonInteractive(t *testing.T) {
+	buff := new(bytes.Buffer)
+	SetOutput(buff)
+	l := NewLog()
+
+	p := l.NewProgress("build", 10)
+	p.Add(3)
+	p.Set(10)
+	p.Done()
+
+	if buff.Len() == 0 {
+		t.Fatal("expected a summary line")
+	}
+	line := buff.String()
+	if !strings.Contains(line, "build") || !strings.Contains(line, "10/10") {
+		t.Errorf("unexpected summary: %q", line)
+	}
+	if strings.Count(line, "\n") != 1 {
+		t.Errorf("expected exactly one line, got %q", line)
+	}
+}
+
+func TestSpinnerNonInteractive(t *testing.T) {
+	buff := new(bytes.Buffer)
+	SetOutput(buff)
+	l := NewLog()
+
+	s := l.NewSpinner("waiting")
+	s.Done()
+
+	line := buff.String()
+	if !strings.Contains(line, "waiting") || !strings.Contains(line, "done in") {
+		t.Errorf("unexpected summary: %q", line)
+	}
+	if strings.Count(line, "\n") != 1 {
+		t.Errorf("expected exactly one line, got %q", line)
+	}
+}
+
+// TestSpinnerDoneTwiceLive exercises the live-mode teardown path, which
+// NewSpinner only takes on a real terminal - liveCapable() is always false
+// in this test binary, so s.live is forced by hand here rather than relying
+// on terminal.IsTerminal.
+func TestSpinnerDoneTwiceLive(t *testing.T) {
+	buff := new(bytes.Buffer)
+	SetOutput(buff)
+	l := NewLog()
+
+	s := &spinner{log: l, header: "waiting", start: time.Now(), stop: make(chan struct{})}
+	s.live = true
+	s.ticker = time.NewTicker(time.Hour)
+
+	s.Done()
+	s.Done()
+}
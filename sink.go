@@ -0,0 +1,167 @@
+package termlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sink is a secondary output destination, added with Log.AddSink. Unlike the
+// Log's primary Handler (set with SetHandler or NewLogWithHandler), a Log can
+// have any number of sinks active at once, each with its own Handler and
+// minimum Level - for instance, coloured output to the terminal alongside
+// JSON lines written to a file.
+type Sink struct {
+	writer   io.Writer
+	handler  Handler
+	minLevel Level
+}
+
+// AddSink adds a sink that writes entries at or above minLevel through h. w
+// is the underlying writer backing h; if w implements io.Closer, it is
+// closed when the sink is removed with RemoveSink. AddSink returns a handle
+// that should be passed to RemoveSink to stop and release the sink.
+func (l *Log) AddSink(w io.Writer, h Handler, minLevel Level) *Sink {
+	s := &Sink{writer: w, handler: h, minLevel: minLevel}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, s)
+	return s
+}
+
+// RemoveSink stops and removes a sink previously added with AddSink. If the
+// sink's writer implements io.Closer, it is closed.
+func (l *Log) RemoveSink(s *Sink) {
+	l.mu.Lock()
+	for i, sk := range l.sinks {
+		if sk == s {
+			l.sinks = append(l.sinks[:i], l.sinks[i+1:]...)
+			break
+		}
+	}
+	l.mu.Unlock()
+	if c, ok := s.writer.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// RotatingFile is an io.WriteCloser over a log file that rotates itself once
+// a write would take it past maxBytes or it gets older than maxAge, whichever
+// comes first (a zero value disables that trigger). On rotation the current
+// file is renamed to "<path>.1"; the segment that was at "<path>.1" is first
+// gzip-compressed to "<path>.1.gz", replacing any segment already there.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxAge   time.Duration
+	f        *os.File
+	size     int64
+	opened   time.Time
+}
+
+// NewRotatingFile opens (creating if necessary) a log file at path for
+// appending, rotating it according to maxBytes and maxAge as writes arrive.
+// This is the building block for persisting logs from long-running
+// interactive tools without losing the coloured handler on stdout - add it
+// alongside the default handler with AddSink.
+//
+// Two prior generations are retained: the most recent one uncompressed at
+// path.1, and the one before it at path.1.gz, which each rotation
+// overwrites. Older generations are not kept, so history beyond the last two
+// rotations is lost.
+func NewRotatingFile(path string, maxBytes int64, maxAge time.Duration) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxBytes: maxBytes, maxAge: maxAge}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = fi.Size()
+	r.opened = fi.ModTime()
+	return nil
+}
+
+// Write implements io.Writer, rotating the file first if p would take it past
+// maxBytes or the file has aged past maxAge.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dueRotate(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) dueRotate(next int) bool {
+	if r.maxBytes > 0 && r.size+int64(next) > r.maxBytes {
+		return true
+	}
+	if r.maxAge > 0 && time.Since(r.opened) > r.maxAge {
+		return true
+	}
+	return false
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	rotated := r.path + ".1"
+	gzipped := rotated + ".gz"
+	if _, err := os.Stat(rotated); err == nil {
+		if err := gzipToFile(rotated, gzipped); err != nil {
+			return err
+		}
+		os.Remove(rotated)
+	}
+	if err := os.Rename(r.path, rotated); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// Close closes the underlying file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// gzipToFile compresses src into dst, overwriting dst if it exists.
+func gzipToFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		gw.Close()
+		return err
+	}
+	return gw.Close()
+}
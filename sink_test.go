@@ -0,0 +1,82 @@
+package termlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.log")
+
+	rf, err := NewRotatingFile(path, 16, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	// This write pushes us past maxBytes, so it should rotate first.
+	if _, err := rf.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated segment: %v", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != 10 {
+		t.Errorf("expected fresh segment with 10 bytes, got %d", fi.Size())
+	}
+}
+
+func TestRotatingFileAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.log")
+
+	rf, err := NewRotatingFile(path, 0, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := rf.Write([]byte("second")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("expected rotated segment: %v", err)
+	}
+}
+
+func TestRotatingFileGzipsOldSegment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.log")
+
+	rf, err := NewRotatingFile(path, 8, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := rf.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1.gz"); err != nil {
+		t.Errorf("expected gzipped segment from the rotation before last: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package termlog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the glyphs a Spinner cycles through while live.
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+const spinnerInterval = 120 * time.Millisecond
+
+// Spinner is a pinned, cycling glyph for a long-running task with no known
+// size - waiting on a network call, an external process, and so on. On a
+// real terminal it animates in place; everywhere else it prints a single
+// summary line when Done is called.
+type Spinner interface {
+	// Done stops the spinner, leaving a single summary line in the scrollback.
+	Done()
+}
+
+type spinner struct {
+	mu       sync.Mutex
+	log      *Log
+	header   string
+	frame    int
+	start    time.Time
+	live     bool
+	ticker   *time.Ticker
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSpinner creates a Spinner with the given header and starts animating
+// it immediately.
+func (l *Log) NewSpinner(header string) Spinner {
+	s := &spinner{log: l, header: header, start: time.Now(), stop: make(chan struct{})}
+	s.live = l.startLive(s)
+	if s.live {
+		s.ticker = time.NewTicker(spinnerInterval)
+		go s.run()
+	}
+	return s
+}
+
+func (s *spinner) run() {
+	for {
+		select {
+		case <-s.ticker.C:
+			s.mu.Lock()
+			s.frame++
+			s.mu.Unlock()
+			s.log.repaintLive(s)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Done stops the spinner and prints a summary line. It is safe to call more
+// than once - only the first call tears down the ticker goroutine.
+func (s *spinner) Done() {
+	if s.live {
+		s.stopOnce.Do(func() {
+			s.ticker.Stop()
+			close(s.stop)
+		})
+		s.log.endLive(s)
+	}
+	elapsed := time.Since(s.start).Round(time.Second)
+	s.log.emit("", LevelSay, nil, "%s", []interface{}{fmt.Sprintf("%s: done in %s", s.header, elapsed)})
+}
+
+// renderLocked implements liveRegion.
+func (s *spinner) renderLocked() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	glyph := spinnerFrames[s.frame%len(spinnerFrames)]
+	return fmt.Sprintf("\r%s %s", glyph, s.header)
+}
@@ -1,90 +1,83 @@
 package termlog
 
-import (
-	"math/rand"
-	"time"
-)
-
+// stream is a Logger that passes each line straight to its Log's output,
+// subject to its own quiet flag.
 type stream struct {
-	header string
-	quiet  bool
-	id     string
-	log    *Log
+	quiet bool
+	log   *Log
 }
 
-var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+// emit implements emitter.
+func (s *stream) emit(name string, lvl Level, fields []KV, format string, args []interface{}) {
+	s.log.output(s.quiet, &line{name, lvl, format, args, fields})
+}
 
-func rndstr(n int) string {
-	b := make([]rune, n)
-	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
-	}
-	return string(b)
+// With returns a Logger that attaches kv to every entry it logs.
+func (s *stream) With(kv ...interface{}) Logger {
+	return &withLogger{base: s, fields: kvFields(kv)}
 }
 
 // Say logs a line
 func (s *stream) Say(format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{"", s.log.format(true, say, format, args), s})
+	s.emit("", LevelSay, nil, format, args)
 }
 
 // Notice logs a line with the Notice color
 func (s *stream) Notice(format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{"", s.log.format(true, notice, format, args), s})
+	s.emit("", LevelNotice, nil, format, args)
 }
 
 // Warn logs a line with the Warn color
 func (s *stream) Warn(format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{"", s.log.format(true, warn, format, args), s})
+	s.emit("", LevelWarn, nil, format, args)
 }
 
 // Shout logs a line with the Shout color
 func (s *stream) Shout(format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{"", s.log.format(true, shout, format, args), s})
+	s.emit("", LevelShout, nil, format, args)
 }
 
 // SayAs logs a line
 func (s *stream) SayAs(name string, format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{name, s.log.format(true, say, format, args), s})
+	s.emit(name, LevelSay, nil, format, args)
 }
 
 // NoticeAs logs a line with the Notice color
 func (s *stream) NoticeAs(name string, format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{name, s.log.format(true, notice, format, args), s})
+	s.emit(name, LevelNotice, nil, format, args)
 }
 
 // WarnAs logs a line with the Warn color
 func (s *stream) WarnAs(name string, format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{name, s.log.format(true, warn, format, args), s})
+	s.emit(name, LevelWarn, nil, format, args)
 }
 
 // ShoutAs logs a line with the Shout color
 func (s *stream) ShoutAs(name string, format string, args ...interface{}) {
-	s.log.output(s.quiet, &line{name, s.log.format(true, shout, format, args), s})
+	s.emit(name, LevelShout, nil, format, args)
 }
 
-// Quiet disables output for this subgroup
-func (s *stream) Quiet() {
-	s.quiet = true
+// SayKV logs a message with structured key/value fields
+func (s *stream) SayKV(msg string, kv ...interface{}) {
+	s.emit("", LevelSay, kvFields(kv), "%s", []interface{}{msg})
 }
 
-// Header immedately outputs the stream header
-func (s *stream) Header() {
-	s.log.mu.Lock()
-	defer s.log.mu.Unlock()
-	s.log.header(s)
+// NoticeKV logs a message with the Notice color and structured key/value fields
+func (s *stream) NoticeKV(msg string, kv ...interface{}) {
+	s.emit("", LevelNotice, kvFields(kv), "%s", []interface{}{msg})
 }
 
-func (s *stream) getID() string {
-	if s.id == "" {
-		s.id = rndstr(16)
-	}
-	return s.id
+// WarnKV logs a message with the Warn color and structured key/value fields
+func (s *stream) WarnKV(msg string, kv ...interface{}) {
+	s.emit("", LevelWarn, kvFields(kv), "%s", []interface{}{msg})
 }
 
-func (s *stream) getHeader() string {
-	return s.header
+// ShoutKV logs a message with the Shout color and structured key/value fields
+func (s *stream) ShoutKV(msg string, kv ...interface{}) {
+	s.emit("", LevelShout, kvFields(kv), "%s", []interface{}{msg})
 }
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// Quiet disables output for this subgroup
+func (s *stream) Quiet() {
+	s.quiet = true
 }
@@ -3,42 +3,46 @@
 package termlog
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"golang.org/x/crypto/ssh/terminal"
-	"golang.org/x/net/context"
 )
 
 const defaultTimeFmt = "15:04:05: "
 const indent = "  "
 
-// Palette defines the colour of output
+// Palette defines the colour of output. Each entry is a PaletteEntry, which
+// can be built from one of fatih/color's 16 standard Attribute colours
+// (NewPaletteEntry), a 256-colour palette index (Color256), or a 24-bit
+// truecolor RGB value (RGB).
 type Palette struct {
-	Timestamp *color.Color
-	Say       *color.Color
-	Notice    *color.Color
-	Warn      *color.Color
-	Shout     *color.Color
+	Timestamp *PaletteEntry
+	Say       *PaletteEntry
+	Notice    *PaletteEntry
+	Warn      *PaletteEntry
+	Shout     *PaletteEntry
 }
 
 // DefaultPalette is a sensbile default palette, with the following foreground
 // colours:
 //
-// 	Say: Terminal default
-// 	Notice: Blue
-// 	Warn: Yellow
-// 	Shout: Red
-// 	Timestamp: Cyan
+//	Say: Terminal default
+//	Notice: Blue
+//	Warn: Yellow
+//	Shout: Red
+//	Timestamp: Cyan
 var DefaultPalette = Palette{
-	Say:       color.New(),
-	Notice:    color.New(color.FgBlue),
-	Warn:      color.New(color.FgYellow),
-	Shout:     color.New(color.FgRed),
-	Timestamp: color.New(color.FgCyan),
+	Say:       &PaletteEntry{},
+	Notice:    NewPaletteEntry(color.FgBlue),
+	Warn:      NewPaletteEntry(color.FgYellow),
+	Shout:     NewPaletteEntry(color.FgRed),
+	Timestamp: NewPaletteEntry(color.FgCyan),
 }
 
 // Logger logs things
@@ -52,6 +56,23 @@ type Logger interface {
 	NoticeAs(name string, format string, args ...interface{})
 	WarnAs(name string, format string, args ...interface{})
 	ShoutAs(name string, format string, args ...interface{})
+
+	SayKV(msg string, kv ...interface{})
+	NoticeKV(msg string, kv ...interface{})
+	WarnKV(msg string, kv ...interface{})
+	ShoutKV(msg string, kv ...interface{})
+
+	// With returns a logger that attaches kv to every entry it logs, in
+	// addition to any fields supplied at the call site. kv is a flat
+	// key/value list, as passed to SayKV and friends.
+	With(kv ...interface{}) Logger
+}
+
+// emitter is the narrow interface With wraps: something that can dispatch a
+// single formatted, fielded log line. Log, group and stream all implement
+// it, which is what lets With() be layered over any of them.
+type emitter interface {
+	emit(name string, lvl Level, fields []KV, format string, args []interface{})
 }
 
 // Group is a collected group of log entries
@@ -70,39 +91,150 @@ type TermLog interface {
 
 type line struct {
 	name   string
-	color  *color.Color
+	level  Level
 	format string
 	args   []interface{}
+	fields []KV
 }
 
 // Log is the top-level log structure
 type Log struct {
-	mu      sync.Mutex
-	Palette *Palette
-	TimeFmt string
-	enabled map[string]bool
-	quiet   bool
+	mu       sync.Mutex
+	Palette  *Palette
+	TimeFmt  string
+	enabled  map[string]bool
+	quiet    bool
+	handler  Handler
+	level    Level
+	levelFor map[string]Level
+	sinks    []*Sink
+	live     liveRegion
 }
 
 // NewLog creates a new Log instance and initialises it with a set of defaults.
 func NewLog() *Log {
 	l := &Log{
-		Palette: &DefaultPalette,
-		enabled: make(map[string]bool),
-		TimeFmt: defaultTimeFmt,
+		Palette:  &DefaultPalette,
+		enabled:  make(map[string]bool),
+		TimeFmt:  defaultTimeFmt,
+		levelFor: make(map[string]Level),
 	}
+	l.handler = &terminalHandler{log: l}
 	l.enabled[""] = true
-	if !terminal.IsTerminal(int(os.Stdout.Fd())) {
+	isTTY := terminal.IsTerminal(int(os.Stdout.Fd()))
+	if isTTY {
+		enableConsole()
+	}
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		l.Color(false)
+	case os.Getenv("FORCE_COLOR") != "":
+		l.Color(true)
+	case !isTTY:
 		l.Color(false)
 	}
+	l.applyEnv(os.Getenv("TERMLOG"))
 	return l
 }
 
+// NewLogWithHandler creates a new Log that dispatches entries to h, instead
+// of the default coloured terminal handler. This is the entry point for
+// structured logging backends such as NewJSONHandler and NewLogfmtHandler.
+func NewLogWithHandler(h Handler) *Log {
+	l := &Log{
+		Palette:  &DefaultPalette,
+		enabled:  make(map[string]bool),
+		TimeFmt:  defaultTimeFmt,
+		levelFor: make(map[string]Level),
+		handler:  h,
+	}
+	l.enabled[""] = true
+	l.applyEnv(os.Getenv("TERMLOG"))
+	return l
+}
+
+// SetLevel sets the minimum level that will be emitted. Entries below this
+// level are dropped in output(), unless overridden for their stream by
+// SetLevelFor. The default level is LevelSay, which emits everything.
+func (l *Log) SetLevel(lvl Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = lvl
+}
+
+// SetLevelFor sets the minimum level for a specific Enable'd stream name,
+// overriding the Log's default level for that name. This lets callers
+// silence noisy streams while keeping warnings, e.g.
+// SetLevelFor("net", LevelWarn).
+func (l *Log) SetLevelFor(name string, lvl Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levelFor[name] = lvl
+}
+
+// applyEnv parses a TERMLOG-style spec ("warn,build=say,net=notice") and
+// applies it to l. Unparseable tokens are ignored.
+func (l *Log) applyEnv(spec string) {
+	if spec == "" {
+		return
+	}
+	for _, tok := range strings.Split(spec, ",") {
+		tok = strings.TrimSpace(tok)
+		if tok == "" {
+			continue
+		}
+		idx := strings.Index(tok, "=")
+		if idx == -1 {
+			if lvl, ok := parseLevel(tok); ok {
+				l.level = lvl
+			}
+			continue
+		}
+		name, levelStr := tok[:idx], tok[idx+1:]
+		if lvl, ok := parseLevel(levelStr); ok {
+			l.levelFor[name] = lvl
+		}
+	}
+}
+
+// SetHandler replaces the Log's output handler.
+func (l *Log) SetHandler(h Handler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handler = h
+}
+
+// color returns the palette colour associated with a level.
+func (l *Log) color(lv Level) *PaletteEntry {
+	switch lv {
+	case LevelNotice:
+		return l.Palette.Notice
+	case LevelWarn:
+		return l.Palette.Warn
+	case LevelShout:
+		return l.Palette.Shout
+	default:
+		return l.Palette.Say
+	}
+}
+
 // Color sets the state of colour output - true to turn on, false to disable.
 func (*Log) Color(state bool) {
 	color.NoColor = !state
 }
 
+// liveCapable reports whether l can repaint an anchored live region -
+// NewProgress and NewSpinner use this to decide whether to animate in place
+// or degrade to a single final line. Live regions only make sense on an
+// actual terminal, with colour (and so cursor control) enabled, using the
+// default terminal handler.
+func (l *Log) liveCapable() bool {
+	if _, ok := l.handler.(*terminalHandler); !ok {
+		return false
+	}
+	return terminal.IsTerminal(int(os.Stdout.Fd())) && !color.NoColor
+}
+
 // Enable logging for a specified name
 func (l *Log) Enable(name string) {
 	l.enabled[name] = true
@@ -122,126 +254,205 @@ func (l *Log) output(quiet bool, lines ...*line) {
 	if len(lines) == 0 {
 		return
 	}
+	if l.live != nil {
+		fmt.Fprint(color.Output, "\r\033[2K")
+	}
+	now := time.Now()
 	first := true
-	for _, line := range lines {
-		if _, ok := l.enabled[line.name]; !ok {
+	for _, ln := range lines {
+		if _, ok := l.enabled[ln.name]; !ok {
 			continue
 		}
-		var format string
-		if first {
-			l.Palette.Timestamp.Printf(
-				"%s", time.Now().Format(l.TimeFmt),
-			)
-			first = false
-			format = line.format + "\n"
-		} else {
-			format = indent + line.format + "\n"
+		threshold := l.level
+		if lvl, ok := l.levelFor[ln.name]; ok {
+			threshold = lvl
+		}
+		if ln.level < threshold {
+			continue
+		}
+		entry := Entry{
+			Time:    now,
+			Level:   ln.level,
+			Stream:  ln.name,
+			Message: fmt.Sprintf(ln.format, ln.args...),
+			Fields:  ln.fields,
+			First:   first,
+		}
+		if l.handler.Enabled(ln.level) {
+			l.handler.Handle(entry)
 		}
-		line.color.Printf(format, line.args...)
+		for _, s := range l.sinks {
+			if ln.level < s.minLevel || !s.handler.Enabled(ln.level) {
+				continue
+			}
+			s.handler.Handle(entry)
+		}
+		first = false
+	}
+	if l.live != nil {
+		fmt.Fprint(color.Output, l.live.renderLocked())
 	}
 }
 
+// emit implements emitter.
+func (l *Log) emit(name string, lvl Level, fields []KV, format string, args []interface{}) {
+	l.output(l.quiet, &line{name, lvl, format, args, fields})
+}
+
+// With returns a Logger that attaches kv to every entry it logs.
+func (l *Log) With(kv ...interface{}) Logger {
+	return &withLogger{base: l, fields: kvFields(kv)}
+}
+
 // Say logs a line
 func (l *Log) Say(format string, args ...interface{}) {
-	l.output(l.quiet, &line{"", l.Palette.Say, format, args})
+	l.emit("", LevelSay, nil, format, args)
 }
 
 // Notice logs a line with the Notice color
 func (l *Log) Notice(format string, args ...interface{}) {
-	l.output(l.quiet, &line{"", l.Palette.Notice, format, args})
+	l.emit("", LevelNotice, nil, format, args)
 }
 
 // Warn logs a line with the Warn color
 func (l *Log) Warn(format string, args ...interface{}) {
-	l.output(l.quiet, &line{"", l.Palette.Warn, format, args})
+	l.emit("", LevelWarn, nil, format, args)
 }
 
 // Shout logs a line with the Shout color
 func (l *Log) Shout(format string, args ...interface{}) {
-	l.output(l.quiet, &line{"", l.Palette.Shout, format, args})
+	l.emit("", LevelShout, nil, format, args)
 }
 
 // SayAs logs a line
 func (l *Log) SayAs(name string, format string, args ...interface{}) {
-	l.output(l.quiet, &line{name, l.Palette.Say, format, args})
+	l.emit(name, LevelSay, nil, format, args)
 }
 
 // NoticeAs logs a line with the Notice color
 func (l *Log) NoticeAs(name string, format string, args ...interface{}) {
-	l.output(l.quiet, &line{name, l.Palette.Notice, format, args})
+	l.emit(name, LevelNotice, nil, format, args)
 }
 
 // WarnAs logs a line with the Warn color
 func (l *Log) WarnAs(name string, format string, args ...interface{}) {
-	l.output(l.quiet, &line{name, l.Palette.Warn, format, args})
+	l.emit(name, LevelWarn, nil, format, args)
 }
 
 // ShoutAs logs a line with the Shout color
 func (l *Log) ShoutAs(name string, format string, args ...interface{}) {
-	l.output(l.quiet, &line{name, l.Palette.Shout, format, args})
+	l.emit(name, LevelShout, nil, format, args)
+}
+
+// SayKV logs a message with structured key/value fields
+func (l *Log) SayKV(msg string, kv ...interface{}) {
+	l.emit("", LevelSay, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// NoticeKV logs a message with the Notice color and structured key/value fields
+func (l *Log) NoticeKV(msg string, kv ...interface{}) {
+	l.emit("", LevelNotice, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// WarnKV logs a message with the Warn color and structured key/value fields
+func (l *Log) WarnKV(msg string, kv ...interface{}) {
+	l.emit("", LevelWarn, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// ShoutKV logs a message with the Shout color and structured key/value fields
+func (l *Log) ShoutKV(msg string, kv ...interface{}) {
+	l.emit("", LevelShout, kvFields(kv), "%s", []interface{}{msg})
 }
 
 // Group creates a new log group
 func (l *Log) Group() Group {
 	return &group{
-		palette: l.Palette,
-		lines:   make([]*line, 0),
-		log:     l,
-		quiet:   l.quiet,
+		lines: make([]*line, 0),
+		log:   l,
+		quiet: l.quiet,
 	}
 }
 
 // Group is a group of lines that constitue a single log entry that won't be
 // split. Lines in a group are indented.
 type group struct {
-	palette *Palette
-	lines   []*line
-	log     *Log
-	quiet   bool
+	lines []*line
+	log   *Log
+	quiet bool
 }
 
-func (g *group) addLine(name string, color *color.Color, format string, args []interface{}) {
-	g.lines = append(g.lines, &line{name, color, format, args})
+func (g *group) addLine(name string, lvl Level, format string, args []interface{}, fields []KV) {
+	g.lines = append(g.lines, &line{name, lvl, format, args, fields})
+}
+
+// emit implements emitter.
+func (g *group) emit(name string, lvl Level, fields []KV, format string, args []interface{}) {
+	g.addLine(name, lvl, format, args, fields)
+}
+
+// With returns a Logger that attaches kv to every entry it logs.
+func (g *group) With(kv ...interface{}) Logger {
+	return &withLogger{base: g, fields: kvFields(kv)}
 }
 
 // Say logs a line
 func (g *group) Say(format string, args ...interface{}) {
-	g.addLine("", g.palette.Say, format, args)
+	g.emit("", LevelSay, nil, format, args)
 }
 
 // Notice logs a line with the Notice color
 func (g *group) Notice(format string, args ...interface{}) {
-	g.addLine("", g.palette.Notice, format, args)
+	g.emit("", LevelNotice, nil, format, args)
 }
 
 // Warn logs a line with the Warn color
 func (g *group) Warn(format string, args ...interface{}) {
-	g.addLine("", g.palette.Warn, format, args)
+	g.emit("", LevelWarn, nil, format, args)
 }
 
 // Shout logs a line with the Shout color
 func (g *group) Shout(format string, args ...interface{}) {
-	g.addLine("", g.palette.Shout, format, args)
+	g.emit("", LevelShout, nil, format, args)
 }
 
 // SayAs logs a line
 func (g *group) SayAs(name string, format string, args ...interface{}) {
-	g.addLine(name, g.palette.Say, format, args)
+	g.emit(name, LevelSay, nil, format, args)
 }
 
 // NoticeAs logs a line with the Notice color
 func (g *group) NoticeAs(name string, format string, args ...interface{}) {
-	g.addLine(name, g.palette.Notice, format, args)
+	g.emit(name, LevelNotice, nil, format, args)
 }
 
 // WarnAs logs a line with the Warn color
 func (g *group) WarnAs(name string, format string, args ...interface{}) {
-	g.addLine(name, g.palette.Warn, format, args)
+	g.emit(name, LevelWarn, nil, format, args)
 }
 
 // ShoutAs logs a line with the Shout color
 func (g *group) ShoutAs(name string, format string, args ...interface{}) {
-	g.addLine(name, g.palette.Shout, format, args)
+	g.emit(name, LevelShout, nil, format, args)
+}
+
+// SayKV logs a message with structured key/value fields
+func (g *group) SayKV(msg string, kv ...interface{}) {
+	g.emit("", LevelSay, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// NoticeKV logs a message with the Notice color and structured key/value fields
+func (g *group) NoticeKV(msg string, kv ...interface{}) {
+	g.emit("", LevelNotice, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// WarnKV logs a message with the Warn color and structured key/value fields
+func (g *group) WarnKV(msg string, kv ...interface{}) {
+	g.emit("", LevelWarn, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// ShoutKV logs a message with the Shout color and structured key/value fields
+func (g *group) ShoutKV(msg string, kv ...interface{}) {
+	g.emit("", LevelShout, kvFields(kv), "%s", []interface{}{msg})
 }
 
 // Done outputs the group to screen
@@ -254,24 +465,13 @@ func (g *group) Quiet() {
 	g.quiet = true
 }
 
-// NewContext creates a new context with an included Logger
-func NewContext(ctx context.Context, logger Logger) context.Context {
-	return context.WithValue(ctx, "termlog", logger)
-}
-
-// FromContext retrieves a Logger from a context. If no logger is present, we
-// return a new silenced logger that will produce no output.
-func FromContext(ctx context.Context) Logger {
-	logger, ok := ctx.Value("termlog").(Logger)
-	if !ok {
-		l := NewLog()
-		l.Quiet()
-		return l
-	}
-	return logger
-}
-
 // SetOutput sets the output writer for termlog (stdout by default).
+//
+// Deprecated: SetOutput is superseded by Log.AddSink, which lets a Log write
+// to any number of destinations with independent handlers and levels.
+// SetOutput is kept only for backward compatibility with code (and tests)
+// written against the older global-writer model, and redirects the package
+// default rather than any one Log's output.
 func SetOutput(w io.Writer) {
 	color.Output = w
 }
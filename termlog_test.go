@@ -2,6 +2,7 @@ package termlog
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -99,6 +100,158 @@ func TestGroup(t *testing.T) {
 	tstring(t, buff, "on - g2 - shout")
 }
 
+func TestJSONHandler(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := NewLogWithHandler(NewJSONHandler(buff))
+	l.Say("hello %s", "world")
+	l.WarnKV("disk low", "path", "/tmp", "pct", 91)
+
+	dec := json.NewDecoder(buff)
+
+	var say map[string]interface{}
+	if err := dec.Decode(&say); err != nil {
+		t.Fatal(err)
+	}
+	if say["level"] != "say" || say["msg"] != "hello world" {
+		t.Errorf("unexpected entry: %v", say)
+	}
+
+	var warn map[string]interface{}
+	if err := dec.Decode(&warn); err != nil {
+		t.Fatal(err)
+	}
+	if warn["level"] != "warn" || warn["msg"] != "disk low" || warn["path"] != "/tmp" {
+		t.Errorf("unexpected entry: %v", warn)
+	}
+}
+
+func TestLogfmtHandler(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := NewLogWithHandler(NewLogfmtHandler(buff))
+	l.NoticeKV("listening", "addr", "localhost:8080")
+
+	line, err := buff.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line, "level=notice") || !strings.Contains(line, "msg=listening") ||
+		!strings.Contains(line, "addr=localhost:8080") {
+		t.Errorf("unexpected line: %s", line)
+	}
+}
+
+func TestJSONHandlerStream(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := NewLogWithHandler(NewJSONHandler(buff))
+	l.Enable("net")
+	l.SayAs("net", "hello")
+
+	var entry map[string]interface{}
+	if err := json.NewDecoder(buff).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["stream"] != "net" {
+		t.Errorf("expected stream %q, got %v", "net", entry["stream"])
+	}
+}
+
+func TestLogfmtHandlerStream(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := NewLogWithHandler(NewLogfmtHandler(buff))
+	l.Enable("net")
+	l.NoticeAs("net", "listening")
+
+	line, err := buff.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line, "stream=net") {
+		t.Errorf("expected stream=net in line, got %q", line)
+	}
+}
+
+func TestAddSink(t *testing.T) {
+	main := new(bytes.Buffer)
+	side := new(bytes.Buffer)
+	SetOutput(main)
+	l := NewLog()
+
+	sink := l.AddSink(side, NewJSONHandler(side), LevelWarn)
+	l.Say("say")
+	l.Warn("warn")
+
+	tstring(t, main, "say")
+	tstring(t, main, "warn")
+
+	if side.Len() == 0 {
+		t.Fatal("expected sink output for warn")
+	}
+	var entry map[string]interface{}
+	if err := json.NewDecoder(side).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "warn" || entry["msg"] != "warn" {
+		t.Errorf("unexpected sink entry: %v", entry)
+	}
+
+	l.RemoveSink(sink)
+	side.Reset()
+	l.Warn("warn again")
+	if side.Len() != 0 {
+		t.Errorf("expected no output after RemoveSink, found %q", side.String())
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	buff := new(bytes.Buffer)
+	SetOutput(buff)
+	l := NewLog()
+	l.Enable("build")
+	l.SetLevel(LevelWarn)
+
+	l.Say("say")
+	l.Notice("notice")
+	l.WarnAs("build", "warn")
+	l.Shout("shout")
+
+	tstring(t, buff, "warn")
+	tstring(t, buff, "shout")
+
+	if buff.Len() != 0 {
+		t.Errorf("expected no further output, found %q", buff.String())
+	}
+}
+
+func TestSetLevelFor(t *testing.T) {
+	buff := new(bytes.Buffer)
+	SetOutput(buff)
+	l := NewLog()
+	l.Enable("net")
+	l.SetLevel(LevelSay)
+	l.SetLevelFor("net", LevelWarn)
+
+	l.NoticeAs("net", "net - notice")
+	l.WarnAs("net", "net - warn")
+	l.Notice("notice")
+
+	tstring(t, buff, "net - warn")
+	tstring(t, buff, "notice")
+}
+
+func TestApplyEnv(t *testing.T) {
+	l := NewLog()
+	l.applyEnv("warn,build=say,net=notice")
+	if l.level != LevelWarn {
+		t.Errorf("expected default level warn, got %v", l.level)
+	}
+	if l.levelFor["build"] != LevelSay {
+		t.Errorf("expected build=say, got %v", l.levelFor["build"])
+	}
+	if l.levelFor["net"] != LevelNotice {
+		t.Errorf("expected net=notice, got %v", l.levelFor["net"])
+	}
+}
+
 func TestContext(t *testing.T) {
 	ctx := context.Background()
 	// Silenced log
@@ -110,3 +263,49 @@ func TestContext(t *testing.T) {
 	b := FromContext(n)
 	b.Shout("something")
 }
+
+func TestWith(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := NewLogWithHandler(NewJSONHandler(buff))
+
+	child := l.With("request", "abc123")
+	child.Say("hello")
+	child.WarnKV("disk low", "pct", 91)
+
+	dec := json.NewDecoder(buff)
+
+	var say map[string]interface{}
+	if err := dec.Decode(&say); err != nil {
+		t.Fatal(err)
+	}
+	if say["request"] != "abc123" || say["msg"] != "hello" {
+		t.Errorf("unexpected entry: %v", say)
+	}
+
+	var warn map[string]interface{}
+	if err := dec.Decode(&warn); err != nil {
+		t.Fatal(err)
+	}
+	if warn["request"] != "abc123" || warn["pct"] != float64(91) {
+		t.Errorf("unexpected entry: %v", warn)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	buff := new(bytes.Buffer)
+	l := NewLogWithHandler(NewJSONHandler(buff))
+
+	ctx := NewContext(context.Background(), l)
+	ctx = WithFields(ctx, "trace", "t1")
+	ctx = WithFields(ctx, "user", "bob")
+
+	FromContext(ctx).Say("hello")
+
+	var entry map[string]interface{}
+	if err := json.NewDecoder(buff).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["trace"] != "t1" || entry["user"] != "bob" || entry["msg"] != "hello" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
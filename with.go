@@ -0,0 +1,82 @@
+package termlog
+
+// withLogger is the Logger returned by Log.With, group.With and
+// stream.With. It layers a fixed set of fields over an emitter, merging
+// them ahead of whatever fields are supplied at each call site.
+type withLogger struct {
+	base   emitter
+	fields []KV
+}
+
+func (w *withLogger) emit(name string, lvl Level, fields []KV, format string, args []interface{}) {
+	merged := make([]KV, 0, len(w.fields)+len(fields))
+	merged = append(merged, w.fields...)
+	merged = append(merged, fields...)
+	w.base.emit(name, lvl, merged, format, args)
+}
+
+// With returns a Logger that attaches kv, layered on top of w's own fields,
+// to every entry it logs.
+func (w *withLogger) With(kv ...interface{}) Logger {
+	return &withLogger{base: w.base, fields: append(append([]KV{}, w.fields...), kvFields(kv)...)}
+}
+
+// Say logs a line
+func (w *withLogger) Say(format string, args ...interface{}) {
+	w.emit("", LevelSay, nil, format, args)
+}
+
+// Notice logs a line with the Notice color
+func (w *withLogger) Notice(format string, args ...interface{}) {
+	w.emit("", LevelNotice, nil, format, args)
+}
+
+// Warn logs a line with the Warn color
+func (w *withLogger) Warn(format string, args ...interface{}) {
+	w.emit("", LevelWarn, nil, format, args)
+}
+
+// Shout logs a line with the Shout color
+func (w *withLogger) Shout(format string, args ...interface{}) {
+	w.emit("", LevelShout, nil, format, args)
+}
+
+// SayAs logs a line
+func (w *withLogger) SayAs(name string, format string, args ...interface{}) {
+	w.emit(name, LevelSay, nil, format, args)
+}
+
+// NoticeAs logs a line with the Notice color
+func (w *withLogger) NoticeAs(name string, format string, args ...interface{}) {
+	w.emit(name, LevelNotice, nil, format, args)
+}
+
+// WarnAs logs a line with the Warn color
+func (w *withLogger) WarnAs(name string, format string, args ...interface{}) {
+	w.emit(name, LevelWarn, nil, format, args)
+}
+
+// ShoutAs logs a line with the Shout color
+func (w *withLogger) ShoutAs(name string, format string, args ...interface{}) {
+	w.emit(name, LevelShout, nil, format, args)
+}
+
+// SayKV logs a message with structured key/value fields
+func (w *withLogger) SayKV(msg string, kv ...interface{}) {
+	w.emit("", LevelSay, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// NoticeKV logs a message with the Notice color and structured key/value fields
+func (w *withLogger) NoticeKV(msg string, kv ...interface{}) {
+	w.emit("", LevelNotice, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// WarnKV logs a message with the Warn color and structured key/value fields
+func (w *withLogger) WarnKV(msg string, kv ...interface{}) {
+	w.emit("", LevelWarn, kvFields(kv), "%s", []interface{}{msg})
+}
+
+// ShoutKV logs a message with the Shout color and structured key/value fields
+func (w *withLogger) ShoutKV(msg string, kv ...interface{}) {
+	w.emit("", LevelShout, kvFields(kv), "%s", []interface{}{msg})
+}